@@ -0,0 +1,23 @@
+package types
+
+import "github.com/ledgerwatch/erigon/common"
+
+// Block couples a Header with the transactions (and, pre-merge, uncles) it
+// commits to via TxHash/UncleHash.
+type Block struct {
+	header       *Header
+	transactions Transactions
+	uncles       []*Header
+}
+
+// NewBlock assembles a Block from a header and its transactions. uncles and
+// receipts are accepted for parity with the upstream constructor signature
+// but are unused post-merge, where uncles are always empty and the receipt
+// root is taken from the header.
+func NewBlock(header *Header, transactions Transactions, uncles []*Header, receipts Receipts) *Block {
+	return &Block{header: header, transactions: transactions, uncles: uncles}
+}
+
+func (b *Block) Header() *Header            { return b.header }
+func (b *Block) Transactions() Transactions { return b.transactions }
+func (b *Block) Hash() common.Hash          { return b.header.Hash() }
@@ -0,0 +1,4 @@
+package types
+
+// Bloom represents a 2048 bit bloom filter over a block's logs.
+type Bloom [256]byte
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// DerivableList is the interface implemented by ordered collections (such as
+// Transactions or a deposit request list) whose root hash is computed by
+// DeriveSha.
+type DerivableList interface {
+	Len() int
+	GetRlp(i int) []byte
+}
+
+// DeriveSha computes the hash used for TxHash, ReceiptHash, and, since
+// EIP-6110, RequestsRoot: a single Keccak-256 over the RLP-encoded index of
+// each element in list followed by its RLP encoding, in list order. It does
+// not build a Merkle-Patricia trie, so the result differs from a trie root
+// over the same list.
+func DeriveSha(list DerivableList) common.Hash {
+	hasher := sha3.NewLegacyKeccak256()
+	for i := 0; i < list.Len(); i++ {
+		key, err := rlp.EncodeToBytes(uint(i))
+		if err != nil {
+			panic(err)
+		}
+		hasher.Write(key)
+		hasher.Write(list.GetRlp(i))
+	}
+	var h common.Hash
+	hasher.Sum(h[:0])
+	return h
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// BlockNonce is a 64-bit hash used to verify that a sufficient amount of
+// computation has been carried out on a block (pre-merge PoW only).
+type BlockNonce [8]byte
+
+// EmptyUncleHash is the known hash of the empty uncle list RLP, used by
+// post-merge blocks which never have uncles.
+var EmptyUncleHash = rlpHash([]*Header(nil))
+
+// Header is a subset of the real erigon block header: only the fields this
+// module's Engine API implementation (ethdb/privateapi) reads or writes.
+type Header struct {
+	ParentHash  common.Hash
+	UncleHash   common.Hash
+	Coinbase    common.Address
+	Root        common.Hash
+	TxHash      common.Hash
+	ReceiptHash common.Hash
+	Bloom       Bloom
+	Difficulty  *big.Int
+	Number      *big.Int
+	GasLimit    uint64
+	GasUsed     uint64
+	Time        uint64
+	Extra       []byte
+	MixDigest   common.Hash
+	Nonce       BlockNonce
+	BaseFee     *big.Int
+
+	// Eip3675 marks a post-merge (PoS) header, at which point Random
+	// replaces MixDigest's PoW meaning and Difficulty/Nonce are frozen at
+	// their Serenity constants.
+	Eip3675 bool
+	// Eip1559 marks a header produced on or after the London fork, at
+	// which point BaseFee is populated.
+	Eip1559 bool
+	// Random is prevRandao, the beacon chain randomness value mixed into
+	// post-merge blocks in place of PoW difficulty.
+	Random common.Hash
+
+	// RequestsRoot is the root hash of the EIP-6110 execution-layer-triggered
+	// requests (deposits, and in later forks withdrawals/consolidations)
+	// included in this block. It is the zero hash pre-Prague.
+	RequestsRoot common.Hash
+}
+
+// Hash returns the block hash of the header, the RLP hash of all its fields.
+func (h *Header) Hash() common.Hash {
+	return rlpHash(h)
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+	_ = rlp.Encode(hasher, x)
+	hasher.Sum(h[:0])
+	return h
+}
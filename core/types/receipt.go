@@ -0,0 +1,18 @@
+package types
+
+import "github.com/ledgerwatch/erigon/common"
+
+// Log is a single event emitted during transaction execution.
+type Log struct {
+	Address common.Address
+	Topics  []common.Hash
+	Data    []byte
+}
+
+// Receipt is the result of executing a single transaction.
+type Receipt struct {
+	Logs []*Log
+}
+
+// Receipts is a block's receipts, in transaction order.
+type Receipts []*Receipt
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// Transaction is implemented by every transaction envelope type (legacy,
+// EIP-2930 access-list, EIP-1559 dynamic-fee, ...).
+type Transaction interface {
+	Hash() common.Hash
+}
+
+// Transactions is a DerivableList of Transaction, so TxHash can be computed
+// with DeriveSha the same way ReceiptHash is.
+type Transactions []Transaction
+
+func (t Transactions) Len() int { return len(t) }
+
+func (t Transactions) GetRlp(i int) []byte {
+	enc, err := rlp.EncodeToBytes(t[i])
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// DecodeTransaction decodes a single RLP-encoded transaction from stream,
+// dispatching on the typed-transaction envelope byte per EIP-2718: a
+// legacy transaction is RLP-encoded directly as a list, while a typed
+// transaction is RLP-encoded as a string whose first byte is the type and
+// the rest is the RLP encoding of the type's fields.
+func DecodeTransaction(stream *rlp.Stream) (Transaction, error) {
+	kind, _, err := stream.Kind()
+	if err != nil {
+		return nil, fmt.Errorf("reading transaction envelope: %w", err)
+	}
+
+	if kind == rlp.List {
+		tx := new(LegacyTx)
+		if err := stream.Decode(tx); err != nil {
+			return nil, fmt.Errorf("decoding legacy transaction: %w", err)
+		}
+		return tx, nil
+	}
+
+	envelope, err := stream.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("reading typed transaction envelope: %w", err)
+	}
+	if len(envelope) == 0 {
+		return nil, fmt.Errorf("empty typed transaction envelope")
+	}
+
+	payload := envelope[1:]
+	switch txType := envelope[0]; txType {
+	case AccessListTxType:
+		tx := new(AccessListTx)
+		if err := rlp.DecodeBytes(payload, tx); err != nil {
+			return nil, fmt.Errorf("decoding EIP-2930 transaction: %w", err)
+		}
+		return tx, nil
+	case DynamicFeeTxType:
+		tx := new(DynamicFeeTx)
+		if err := rlp.DecodeBytes(payload, tx); err != nil {
+			return nil, fmt.Errorf("decoding EIP-1559 transaction: %w", err)
+		}
+		return tx, nil
+	default:
+		return nil, fmt.Errorf("unsupported transaction type: %d", txType)
+	}
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+// Transaction type bytes, per EIP-2718. 0 is reserved for the untyped
+// (legacy) envelope, which has no type byte at all - it is identified by
+// its RLP list header instead.
+const (
+	AccessListTxType = 1 // EIP-2930
+	DynamicFeeTxType = 2 // EIP-1559
+)
+
+// AccessTuple is a single (address, storage keys) entry of an EIP-2930
+// access list.
+type AccessTuple struct {
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+// LegacyTx is the original, untyped transaction envelope.
+type LegacyTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *common.Address
+	Value    *big.Int
+	Data     []byte
+	V, R, S  *big.Int
+}
+
+func (tx *LegacyTx) Hash() common.Hash { return rlpHash(tx) }
+
+// AccessListTx is the EIP-2930 typed transaction: a legacy transaction plus
+// an access list, still without a base fee market.
+type AccessListTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         *common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList []AccessTuple
+	V, R, S    *big.Int
+}
+
+func (tx *AccessListTx) Hash() common.Hash { return rlpHash(tx) }
+
+// DynamicFeeTx is the EIP-1559 typed transaction, replacing GasPrice with a
+// priority-fee/max-fee pair.
+type DynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList []AccessTuple
+	V, R, S    *big.Int
+}
+
+func (tx *DynamicFeeTx) Hash() common.Hash { return rlpHash(tx) }
@@ -0,0 +1,70 @@
+package privateapi
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthRPCConfig describes the authenticated Engine API listener, analogous
+// to geth's authrpc.addr / authrpc.port / authrpc.vhosts / --jwt-secret
+// flags. Engine* methods are served here instead of on the regular
+// ETHBACKEND listener, behind JWTUnaryInterceptor and VhostInterceptor.
+type AuthRPCConfig struct {
+	Addr          string
+	Port          int
+	Vhosts        []string
+	JWTSecretPath string
+}
+
+// DefaultAuthRPCConfig mirrors geth's authrpc defaults.
+func DefaultAuthRPCConfig(jwtSecretPath string) AuthRPCConfig {
+	return AuthRPCConfig{
+		Addr:          DefaultEngineAPIHost,
+		Port:          DefaultEngineAPIPort,
+		Vhosts:        []string{"localhost"},
+		JWTSecretPath: jwtSecretPath,
+	}
+}
+
+// VhostInterceptor returns a gRPC unary interceptor enforcing vhosts the
+// same way geth's authrpc.vhosts does: it rejects any call whose gRPC
+// ":authority" pseudo-header (the HTTP/2 equivalent of the Host header)
+// isn't in vhosts, which is what actually stops a malicious website from
+// DNS-rebinding a browser into talking to the Engine API port. An empty
+// vhosts, or a literal "*" entry, disables the check.
+func VhostInterceptor(vhosts []string) grpc.UnaryServerInterceptor {
+	allowAll := len(vhosts) == 0
+	allowed := make(map[string]bool, len(vhosts))
+	for _, h := range vhosts {
+		if h == "*" {
+			allowAll = true
+		}
+		allowed[h] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if allowAll {
+			return handler(ctx, req)
+		}
+
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok || len(md.Get(":authority")) == 0 {
+			return nil, status.Error(codes.PermissionDenied, "missing :authority header")
+		}
+
+		authority := md.Get(":authority")[0]
+		host := authority
+		if h, _, err := net.SplitHostPort(authority); err == nil {
+			host = h
+		}
+		if !allowed[host] {
+			return nil, status.Errorf(codes.PermissionDenied, "%s is not an allowed vhost", host)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,41 @@
+package privateapi
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// authenticatedMethods is the explicit allow-list of ETHBACKEND gRPC methods
+// served only on the JWT-authenticated Engine API listener. A bare "Engine"
+// name-prefix heuristic isn't enough: ExchangeTransitionConfigurationV1 and
+// EngineHealth are Engine API methods by spec, but only one of them actually
+// starts with "Engine".
+var authenticatedMethods = map[string]bool{
+	"EngineExecutePayloadV1":            true,
+	"EngineExecutePayloadV3":            true,
+	"EngineGetPayloadV1":                true,
+	"EngineGetPayloadV2":                true,
+	"EngineGetPayloadV3":                true,
+	"EngineForkchoiceUpdatedV1":         true,
+	"ExchangeTransitionConfigurationV1": true,
+	"EngineHealth":                      true,
+}
+
+func isEngineMethod(fullMethod string) bool {
+	idx := strings.LastIndex(fullMethod, "/")
+	return authenticatedMethods[fullMethod[idx+1:]]
+}
+
+// PublicAPIInterceptor rejects Engine API calls on the unauthenticated
+// ETHBACKEND listener. Engine methods must only be served on the
+// JWT-authenticated listener set up with JWTUnaryInterceptor.
+func PublicAPIInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isEngineMethod(info.FullMethod) {
+		return nil, status.Errorf(codes.Unimplemented, "%s is only available on the authenticated Engine API endpoint", info.FullMethod)
+	}
+	return handler(ctx, req)
+}
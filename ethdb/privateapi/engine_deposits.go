@@ -0,0 +1,161 @@
+package privateapi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/crypto"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// depositEventSignature is topic0 of the beacon deposit contract's
+// DepositEvent(bytes,bytes,bytes,bytes,bytes), used to pick deposit logs out
+// of a block's receipts for EIP-6110.
+var depositEventSignature = crypto.Keccak256Hash([]byte("DepositEvent(bytes,bytes,bytes,bytes,bytes)"))
+
+// DepositRequest is the EIP-6110 execution-layer-triggered deposit request,
+// ABI-decoded from a DepositEvent log emitted by the beacon deposit
+// contract.
+type DepositRequest struct {
+	Pubkey                [48]byte
+	WithdrawalCredentials [32]byte
+	Amount                uint64
+	Signature             [96]byte
+	Index                 uint64
+}
+
+// depositEventWordSize is the size in bytes of one ABI word in a
+// DepositEvent log's data, which holds 5 dynamic-bytes offset words, then
+// each field's length word and bytes padded to a word boundary.
+const depositEventWordSize = 32
+
+// ParseDepositRequests extracts the EIP-6110 deposit requests triggered by
+// an executed block, by scanning its receipts for DepositEvent logs emitted
+// by the configured deposit contract, in receipt/log order.
+func ParseDepositRequests(receipts types.Receipts, depositContractAddress common.Address) ([]*DepositRequest, error) {
+	var requests []*DepositRequest
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address != depositContractAddress {
+				continue
+			}
+			if len(l.Topics) == 0 || l.Topics[0] != depositEventSignature {
+				continue
+			}
+			req, err := decodeDepositLog(l.Data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DepositEvent log: %w", err)
+			}
+			requests = append(requests, req)
+		}
+	}
+	return requests, nil
+}
+
+// decodeDepositLog ABI-decodes the flat log data of a DepositEvent into
+// (pubkey [48]byte, withdrawal_credentials [32]byte, amount uint64,
+// signature [96]byte, index uint64). The ABI layout is five dynamic `bytes`
+// values, each encoded as [offset...][length][data, padded to a word].
+func decodeDepositLog(data []byte) (*DepositRequest, error) {
+	dataLen := uint64(len(data))
+
+	// remaining reports how much of data is left from position pos, or
+	// false if pos is already past the end - the safe way to check
+	// "pos+need <= dataLen" for a log-controlled pos that can be anywhere
+	// up to 2^64-1, where computing pos+need directly could overflow and
+	// wrap into a small value that passes the check and then panics on the
+	// slice expression below.
+	remaining := func(pos uint64) (uint64, bool) {
+		if pos > dataLen {
+			return 0, false
+		}
+		return dataLen - pos, true
+	}
+
+	readBytesField := func(wordIdx int) ([]byte, error) {
+		offsetPos := uint64(wordIdx) * depositEventWordSize
+		if left, ok := remaining(offsetPos); !ok || left < depositEventWordSize {
+			return nil, fmt.Errorf("log data too short for field %d", wordIdx)
+		}
+		offset := new(big.Int).SetBytes(data[offsetPos : offsetPos+depositEventWordSize]).Uint64()
+		left, ok := remaining(offset)
+		if !ok || left < depositEventWordSize {
+			return nil, fmt.Errorf("log data too short for field %d length", wordIdx)
+		}
+		length := new(big.Int).SetBytes(data[offset : offset+depositEventWordSize]).Uint64()
+		start := offset + depositEventWordSize // safe: offset+depositEventWordSize <= dataLen, checked above
+		left, ok = remaining(start)
+		if !ok || left < length {
+			return nil, fmt.Errorf("log data too short for field %d contents", wordIdx)
+		}
+		return data[start : start+length], nil
+	}
+
+	pubkey, err := readBytesField(0)
+	if err != nil {
+		return nil, err
+	}
+	withdrawalCredentials, err := readBytesField(1)
+	if err != nil {
+		return nil, err
+	}
+	amountBytes, err := readBytesField(2)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := readBytesField(3)
+	if err != nil {
+		return nil, err
+	}
+	indexBytes, err := readBytesField(4)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubkey) != 48 || len(withdrawalCredentials) != 32 || len(signature) != 96 || len(amountBytes) != 8 || len(indexBytes) != 8 {
+		return nil, fmt.Errorf("unexpected field length in DepositEvent log")
+	}
+
+	req := &DepositRequest{
+		Amount: binary.LittleEndian.Uint64(amountBytes),
+		Index:  binary.LittleEndian.Uint64(indexBytes),
+	}
+	copy(req.Pubkey[:], pubkey)
+	copy(req.WithdrawalCredentials[:], withdrawalCredentials)
+	copy(req.Signature[:], signature)
+	return req, nil
+}
+
+// depositRequestsDerivableList adapts []*DepositRequest to types.DerivableList
+// so that types.DeriveSha can build the RequestsRoot the same way it builds
+// TxHash/ReceiptHash.
+type depositRequestsDerivableList []*DepositRequest
+
+func (l depositRequestsDerivableList) Len() int { return len(l) }
+
+func (l depositRequestsDerivableList) GetRlp(i int) []byte {
+	enc, err := rlp.EncodeToBytes(l[i])
+	if err != nil {
+		panic(err) // DepositRequest only has fixed-size fields, encoding cannot fail
+	}
+	return enc
+}
+
+// DeriveDepositRequestsRoot computes RequestsRoot from the deposit requests
+// the CL included in the payload.
+func DeriveDepositRequestsRoot(requests []*DepositRequest) common.Hash {
+	return types.DeriveSha(depositRequestsDerivableList(requests))
+}
+
+// DeriveDepositRequestsRootFromReceipts independently re-derives
+// RequestsRoot from the receipts produced by actually executing the block,
+// so EngineExecutePayloadV3 can catch a CL that lied about its deposits.
+func DeriveDepositRequestsRootFromReceipts(receipts types.Receipts, depositContractAddress common.Address) (common.Hash, error) {
+	requests, err := ParseDepositRequests(receipts, depositContractAddress)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return DeriveDepositRequestsRoot(requests), nil
+}
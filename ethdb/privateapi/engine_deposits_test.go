@@ -0,0 +1,130 @@
+package privateapi
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// encodeDepositLog builds well-formed DepositEvent log data for the five
+// dynamic bytes fields, mirroring the ABI layout decodeDepositLog expects:
+// one offset word per field, followed by each field's length word and
+// word-padded contents, in field order.
+func encodeDepositLog(fields [][]byte) []byte {
+	word := func(v uint64) []byte {
+		b := make([]byte, depositEventWordSize)
+		new(big.Int).SetUint64(v).FillBytes(b)
+		return b
+	}
+	pad := func(b []byte) []byte {
+		if rem := len(b) % depositEventWordSize; rem != 0 {
+			b = append(b, make([]byte, depositEventWordSize-rem)...)
+		}
+		return b
+	}
+
+	var data []byte
+	offset := uint64(len(fields)) * depositEventWordSize
+	offsets := make([]uint64, len(fields))
+	for i, f := range fields {
+		offsets[i] = offset
+		offset += depositEventWordSize + uint64(len(pad(append([]byte{}, f...))))
+	}
+	for _, o := range offsets {
+		data = append(data, word(o)...)
+	}
+	for _, f := range fields {
+		data = append(data, word(uint64(len(f)))...)
+		data = append(data, pad(append([]byte{}, f...))...)
+	}
+	return data
+}
+
+func validDepositLogFields() [][]byte {
+	amount := make([]byte, 8)
+	binary.LittleEndian.PutUint64(amount, 32_000_000_000)
+	index := make([]byte, 8)
+	binary.LittleEndian.PutUint64(index, 7)
+	return [][]byte{
+		make([]byte, 48), // pubkey
+		make([]byte, 32), // withdrawal credentials
+		amount,
+		make([]byte, 96), // signature
+		index,
+	}
+}
+
+func TestDecodeDepositLog_WellFormed(t *testing.T) {
+	data := encodeDepositLog(validDepositLogFields())
+
+	req, err := decodeDepositLog(data)
+	if err != nil {
+		t.Fatalf("expected a well-formed DepositEvent log to decode, got: %v", err)
+	}
+	if req.Amount != 32_000_000_000 || req.Index != 7 {
+		t.Fatalf("decoded unexpected amount/index: %+v", req)
+	}
+}
+
+func TestDecodeDepositLog_EmptyData(t *testing.T) {
+	if _, err := decodeDepositLog(nil); err == nil {
+		t.Fatal("expected empty log data to be rejected")
+	}
+}
+
+func TestDecodeDepositLog_TruncatedOffsetWord(t *testing.T) {
+	data := encodeDepositLog(validDepositLogFields())
+	// Cut the data off partway through the first offset word.
+	if _, err := decodeDepositLog(data[:depositEventWordSize-1]); err == nil {
+		t.Fatal("expected a truncated offset word to be rejected")
+	}
+}
+
+func TestDecodeDepositLog_OffsetPastEnd(t *testing.T) {
+	data := encodeDepositLog(validDepositLogFields())
+	// Point the first field's offset past the end of the data.
+	word := make([]byte, depositEventWordSize)
+	new(big.Int).SetUint64(uint64(len(data)) + 1).FillBytes(word)
+	copy(data[:depositEventWordSize], word)
+
+	if _, err := decodeDepositLog(data); err == nil {
+		t.Fatal("expected an offset pointing past the end of the data to be rejected")
+	}
+}
+
+func TestDecodeDepositLog_LengthExceedsRemainingData(t *testing.T) {
+	data := encodeDepositLog(validDepositLogFields())
+	// Inflate the first field's length word far beyond what actually follows.
+	lengthPos := uint64(len(validDepositLogFields())) * depositEventWordSize
+	word := make([]byte, depositEventWordSize)
+	new(big.Int).SetUint64(1 << 40).FillBytes(word)
+	copy(data[lengthPos:lengthPos+depositEventWordSize], word)
+
+	if _, err := decodeDepositLog(data); err == nil {
+		t.Fatal("expected a length claiming more bytes than remain to be rejected")
+	}
+}
+
+func TestDecodeDepositLog_OffsetNearMaxUint64DoesNotPanic(t *testing.T) {
+	data := encodeDepositLog(validDepositLogFields())
+	// A log-controlled offset near math.MaxUint64 must not overflow the
+	// offset+32 bounds check and panic on the subsequent slice expression.
+	word := make([]byte, depositEventWordSize)
+	new(big.Int).SetUint64(math.MaxUint64 - 1).FillBytes(word)
+	copy(data[:depositEventWordSize], word)
+
+	if _, err := decodeDepositLog(data); err == nil {
+		t.Fatal("expected an offset near math.MaxUint64 to be rejected, not accepted")
+	}
+}
+
+func TestDecodeDepositLog_UnexpectedFieldLength(t *testing.T) {
+	fields := validDepositLogFields()
+	fields[0] = make([]byte, 20) // pubkey must be 48 bytes
+	data := encodeDepositLog(fields)
+
+	if _, err := decodeDepositLog(data); err == nil {
+		t.Fatal("expected a field with the wrong decoded length to be rejected")
+	}
+}
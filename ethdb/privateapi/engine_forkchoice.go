@@ -0,0 +1,215 @@
+package privateapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/rawdb"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rlp"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// maxPayloadBuildTime bounds how long the background rebuild loop keeps
+// trying to improve a commissioned payload's tx set. It mirrors the
+// Kiln-v2 spec's expectation that the CL will call GetPayload roughly once
+// per slot (12s).
+const maxPayloadBuildTime = 12 * time.Second
+
+// payloadBuilderSet tracks the background rebuild goroutines started by
+// ForkchoiceUpdatedV1, keyed by payloadId, so that EngineGetPayloadV1/V2 can
+// stop one once the CL has retrieved its payload.
+type payloadBuilderSet struct {
+	mu    sync.Mutex
+	stops map[uint64]chan struct{}
+}
+
+func newPayloadBuilderSet() *payloadBuilderSet {
+	return &payloadBuilderSet{
+		stops: make(map[uint64]chan struct{}),
+	}
+}
+
+func (b *payloadBuilderSet) start(id uint64) chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if stop, ok := b.stops[id]; ok {
+		return stop
+	}
+	stop := make(chan struct{})
+	b.stops[id] = stop
+	return stop
+}
+
+func (b *payloadBuilderSet) stop(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if stop, ok := b.stops[id]; ok {
+		close(stop)
+		delete(b.stops, id)
+	}
+}
+
+// computePayloadId derives the payloadId the CL will use to retrieve a
+// commissioned payload: the first 8 bytes of
+// sha256(headHash || timestamp || prevRandao || feeRecipient), big-endian.
+func computePayloadId(headHash common.Hash, timestamp uint64, prevRandao common.Hash, feeRecipient common.Address) uint64 {
+	h := sha256.New()
+	h.Write(headHash.Bytes())
+	var timestampBytes [8]byte
+	binary.BigEndian.PutUint64(timestampBytes[:], timestamp)
+	h.Write(timestampBytes[:])
+	h.Write(prevRandao.Bytes())
+	h.Write(feeRecipient.Bytes())
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// EngineForkchoiceUpdatedV1 updates the node's view of head/safe/finalized
+// and, when payloadAttributes is set, commissions asynchronous block
+// building so that a later EngineGetPayloadV1 can retrieve the result.
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
+func (s *EthBackendServer) EngineForkchoiceUpdatedV1(ctx context.Context, req *remote.EngineForkChoiceUpdatedRequest) (*remote.EngineForkChoiceUpdatedReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
+	if s.config.TerminalTotalDifficulty == nil {
+		return nil, fmt.Errorf("not a proof-of-stake chain")
+	}
+
+	headHash := gointerfaces.ConvertH256ToHash(req.HeadBlockHash)
+	safeHash := gointerfaces.ConvertH256ToHash(req.SafeBlockHash)
+	finalizedHash := gointerfaces.ConvertH256ToHash(req.FinalizedBlockHash)
+
+	tx, err := s.db.BeginRw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	headNumber := rawdb.ReadHeaderNumber(tx, headHash)
+	if headNumber == nil {
+		return &remote.EngineForkChoiceUpdatedReply{
+			Status: Syncing,
+		}, nil
+	}
+
+	rawdb.WriteForkchoiceHead(tx, headHash)
+	rawdb.WriteForkchoiceSafe(tx, safeHash)
+	rawdb.WriteForkchoiceFinalized(tx, finalizedHash)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	reply := &remote.EngineForkChoiceUpdatedReply{
+		Status:          Valid,
+		LatestValidHash: gointerfaces.ConvertHashToH256(headHash),
+	}
+
+	attrs := req.PayloadAttributes
+	if attrs == nil {
+		return reply, nil
+	}
+
+	timestamp := attrs.Timestamp
+	prevRandao := gointerfaces.ConvertH256ToHash(attrs.PrevRandao)
+	feeRecipient := gointerfaces.ConvertH160toAddress(attrs.SuggestedFeeRecipient)
+
+	payloadId := computePayloadId(headHash, timestamp, prevRandao, feeRecipient)
+	reply.PayloadId = payloadId
+
+	stop := s.builders.start(payloadId)
+	go s.buildPayloadLoop(payloadId, stop, headHash, timestamp, prevRandao, feeRecipient)
+
+	return reply, nil
+}
+
+// buildPayloadLoop repeatedly re-assembles the block for payloadId, storing
+// progressively better results in s.payloads, until stop fires (GetPayload
+// was called) or maxPayloadBuildTime elapses.
+func (s *EthBackendServer) buildPayloadLoop(payloadId uint64, stop <-chan struct{}, parentHash common.Hash, timestamp uint64, prevRandao common.Hash, feeRecipient common.Address) {
+	deadline := time.NewTimer(maxPayloadBuildTime)
+	defer deadline.Stop()
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	build := func() {
+		block, receipts, err := s.eth.BuildPayload(parentHash, timestamp, prevRandao, feeRecipient)
+		if err != nil {
+			log.Warn("Failed to build payload", "payloadId", payloadId, "err", err)
+			return
+		}
+		var depositRequests []*DepositRequest
+		if s.config.DepositContractAddress != (common.Address{}) {
+			if depositRequests, err = ParseDepositRequests(receipts, s.config.DepositContractAddress); err != nil {
+				log.Warn("Failed to parse deposit requests while building payload", "payloadId", payloadId, "err", err)
+			}
+		}
+		s.payloads.put(payloadId, blockToExecutionPayload(block), depositRequests, block)
+	}
+
+	build()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+			build()
+		case <-s.ctx.Done():
+			return
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+// blockToExecutionPayload converts a locally-built block into the wire
+// ExecutionPayload representation returned by EngineGetPayloadV1.
+func blockToExecutionPayload(block *types.Block) types2.ExecutionPayload {
+	header := block.Header()
+
+	encodedTransactions := make([][]byte, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		encoded, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			log.Warn("Failed to RLP-encode transaction for payload", "err", err)
+			continue
+		}
+		encodedTransactions[i] = encoded
+	}
+
+	payload := types2.ExecutionPayload{
+		ParentHash:   gointerfaces.ConvertHashToH256(header.ParentHash),
+		Coinbase:     gointerfaces.ConvertAddressToH160(header.Coinbase),
+		StateRoot:    gointerfaces.ConvertHashToH256(header.Root),
+		ReceiptRoot:  gointerfaces.ConvertHashToH256(header.ReceiptHash),
+		LogsBloom:    gointerfaces.ConvertBloomToH2048(header.Bloom),
+		Random:       gointerfaces.ConvertHashToH256(header.Random),
+		BlockNumber:  header.Number.Uint64(),
+		GasLimit:     header.GasLimit,
+		GasUsed:      header.GasUsed,
+		Timestamp:    header.Time,
+		ExtraData:    gointerfaces.ConvertHashToH256(common.BytesToHash(header.Extra)),
+		BlockHash:    gointerfaces.ConvertHashToH256(header.Hash()),
+		Transactions: encodedTransactions,
+	}
+	if header.BaseFee != nil {
+		baseFee, _ := uint256.FromBig(header.BaseFee)
+		payload.BaseFeePerGas = gointerfaces.ConvertUint256IntToH256(baseFee)
+	}
+	return payload
+}
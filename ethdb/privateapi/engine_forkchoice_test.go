@@ -0,0 +1,31 @@
+package privateapi
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+func TestComputePayloadId_Deterministic(t *testing.T) {
+	head := common.HexToHash("0x01")
+	prevRandao := common.HexToHash("0x02")
+	feeRecipient := common.HexToAddress("0x03")
+
+	a := computePayloadId(head, 1, prevRandao, feeRecipient)
+	b := computePayloadId(head, 1, prevRandao, feeRecipient)
+	if a != b {
+		t.Fatalf("computePayloadId is not deterministic: got %d and %d for identical inputs", a, b)
+	}
+}
+
+func TestComputePayloadId_DiffersOnTimestamp(t *testing.T) {
+	head := common.HexToHash("0x01")
+	prevRandao := common.HexToHash("0x02")
+	feeRecipient := common.HexToAddress("0x03")
+
+	a := computePayloadId(head, 1, prevRandao, feeRecipient)
+	b := computePayloadId(head, 2, prevRandao, feeRecipient)
+	if a == b {
+		t.Fatal("expected computePayloadId to differ when timestamp differs")
+	}
+}
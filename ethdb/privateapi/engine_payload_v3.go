@@ -0,0 +1,80 @@
+package privateapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// EngineExecutePayloadV3 is EngineExecutePayloadV1 extended with EIP-6110
+// deposit requests (Prague+). V1/V2 callers keep working unchanged since
+// they go through executePayload with depositRequests == nil.
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
+func (s *EthBackendServer) EngineExecutePayloadV3(ctx context.Context, req *types2.ExecutionPayloadV3) (*remote.EngineExecutePayloadReply, error) {
+	if req == nil {
+		return nil, fmt.Errorf("invalid execution payload")
+	}
+
+	depositRequests, err := decodeDepositRequests(req.DepositRequests)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit requests: %w", err)
+	}
+
+	reply, _, err := s.executePayload(ctx, req.Payload, depositRequests)
+	return reply, err
+}
+
+// EngineGetPayloadV3 is EngineGetPayloadV1 extended with the deposit
+// requests assembled for the payload, for the CL to re-broadcast.
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
+func (s *EthBackendServer) EngineGetPayloadV3(ctx context.Context, req *remote.EngineGetPayloadRequest) (*types2.ExecutionPayloadV3, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
+	if s.config.TerminalTotalDifficulty == nil {
+		return nil, fmt.Errorf("not a proof-of-stake chain")
+	}
+
+	// payload and deposit requests must come from one locked read, see getEntry.
+	payload, _, depositRequests, ok := s.payloads.getEntry(req.PayloadId)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload")
+	}
+	// The CL retrieved the payload, so there is no point building on it any further.
+	s.builders.stop(req.PayloadId)
+
+	encoded, err := encodeDepositRequests(depositRequests)
+	if err != nil {
+		return nil, err
+	}
+	return &types2.ExecutionPayloadV3{Payload: &payload, DepositRequests: encoded}, nil
+}
+
+func decodeDepositRequests(encoded [][]byte) ([]*DepositRequest, error) {
+	requests := make([]*DepositRequest, len(encoded))
+	for i, enc := range encoded {
+		req := new(DepositRequest)
+		if err := rlp.DecodeBytes(enc, req); err != nil {
+			return nil, err
+		}
+		requests[i] = req
+	}
+	return requests, nil
+}
+
+func encodeDepositRequests(requests []*DepositRequest) ([][]byte, error) {
+	encoded := make([][]byte, len(requests))
+	for i, req := range requests {
+		enc, err := rlp.EncodeToBytes(req)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = enc
+	}
+	return encoded, nil
+}
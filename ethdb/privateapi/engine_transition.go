@@ -0,0 +1,87 @@
+package privateapi
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces"
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/log/v3"
+)
+
+// ExchangeTransitionConfigurationV1 reconciles the CL's view of the
+// terminal PoW block with erigon's own, logging a warning on mismatch so
+// operators notice a misconfigured TTD/terminal block before the merge
+// transition stalls. It always returns erigon's local view, as the spec
+// requires.
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
+func (s *EthBackendServer) ExchangeTransitionConfigurationV1(ctx context.Context, req *remote.EngineExchangeTransitionConfigurationRequest) (*remote.EngineExchangeTransitionConfigurationReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
+	atomic.StoreInt64(&s.lastBeaconCall, time.Now().UnixNano())
+
+	if s.config.TerminalTotalDifficulty == nil {
+		return nil, fmt.Errorf("not a proof-of-stake chain")
+	}
+
+	localTTD := s.config.TerminalTotalDifficulty
+	remoteTTD := gointerfaces.ConvertH256ToUint256Int(req.TerminalTotalDifficulty).ToBig()
+	if localTTD.Cmp(remoteTTD) != 0 {
+		log.Warn("Terminal total difficulty mismatch between erigon and the consensus client",
+			"local", localTTD, "remote", remoteTTD)
+	}
+
+	var localTerminalHash common.Hash
+	var localTerminalNumber uint64
+	if s.config.TerminalBlockHash != (common.Hash{}) {
+		localTerminalHash = s.config.TerminalBlockHash
+		localTerminalNumber = s.config.TerminalBlockNumber.Uint64()
+	} else {
+		tx, err := s.db.BeginRo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		if header, err := s.blockReader.HeaderByNumber(ctx, tx, req.TerminalBlockNumber); err == nil && header != nil {
+			localTerminalHash = header.Hash()
+			localTerminalNumber = header.Number.Uint64()
+		}
+	}
+
+	remoteTerminalHash := gointerfaces.ConvertH256ToHash(req.TerminalBlockHash)
+	if localTerminalHash != (common.Hash{}) && remoteTerminalHash != localTerminalHash {
+		log.Warn("Terminal block hash mismatch between erigon and the consensus client",
+			"local", localTerminalHash, "remote", remoteTerminalHash)
+	}
+
+	localTTD256, _ := uint256.FromBig(localTTD)
+	return &remote.EngineExchangeTransitionConfigurationReply{
+		TerminalTotalDifficulty: gointerfaces.ConvertUint256IntToH256(localTTD256),
+		TerminalBlockHash:       gointerfaces.ConvertHashToH256(localTerminalHash),
+		TerminalBlockNumber:     localTerminalNumber,
+	}, nil
+}
+
+// EngineHealth reports whether this node has heard from the consensus
+// client recently enough for orchestration systems to alert on a missing
+// beacon connection.
+func (s *EthBackendServer) EngineHealth(ctx context.Context, _ *remote.EngineHealthRequest) (*remote.EngineHealthReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
+	last := atomic.LoadInt64(&s.lastBeaconCall)
+	if last == 0 || time.Since(time.Unix(0, last)) > beaconCallTimeout {
+		return &remote.EngineHealthReply{Status: Unhealthy}, nil
+	}
+	return &remote.EngineHealthReply{Status: Healthy}, nil
+}
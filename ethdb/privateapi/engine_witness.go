@@ -0,0 +1,178 @@
+package privateapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/core/types"
+	"github.com/ledgerwatch/erigon/rlp"
+)
+
+// Witness is everything a stateless verifier needs to re-execute a payload
+// without access to state: the MPT nodes (accounts, storage, code) touched
+// while building it, the contract bytecodes those nodes reference, and the
+// ancestor headers any BLOCKHASH opcode looked up.
+type Witness struct {
+	Headers []*types.Header
+	Codes   [][]byte
+	State   [][]byte
+}
+
+// WitnessRecorder wraps the state reader used during block building and
+// records every trie node, contract bytecode, and BLOCKHASH lookup it
+// observes, so that Finalize can assemble a Witness afterwards. It is safe
+// for concurrent use since a block is typically executed with multiple
+// transactions running against the same reader.
+type WitnessRecorder struct {
+	mu sync.Mutex
+
+	nodes     map[common.Hash][]byte
+	codes     map[common.Hash][]byte
+	headers   map[common.Hash]*types.Header
+	preStates map[common.Hash][]byte
+}
+
+// NewWitnessRecorder creates an empty recorder. Call RecordNode/RecordCode/
+// RecordHeader/RecordPreState as the block executes, then Finalize once.
+func NewWitnessRecorder() *WitnessRecorder {
+	return &WitnessRecorder{
+		nodes:     make(map[common.Hash][]byte),
+		codes:     make(map[common.Hash][]byte),
+		headers:   make(map[common.Hash]*types.Header),
+		preStates: make(map[common.Hash][]byte),
+	}
+}
+
+// RecordNode stores an MPT node (account, storage, or intermediate trie
+// node) keyed by its hash, deduplicating as it goes.
+func (w *WitnessRecorder) RecordNode(nodeHash common.Hash, nodeRLP []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.nodes[nodeHash]; !ok {
+		w.nodes[nodeHash] = common.CopyBytes(nodeRLP)
+	}
+}
+
+// RecordCode stores a contract's bytecode keyed by its code hash.
+func (w *WitnessRecorder) RecordCode(codeHash common.Hash, code []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.codes[codeHash]; !ok {
+		w.codes[codeHash] = common.CopyBytes(code)
+	}
+}
+
+// RecordHeader stores an ancestor header referenced by a BLOCKHASH lookup.
+func (w *WitnessRecorder) RecordHeader(header *types.Header) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.headers[header.Hash()] = header
+}
+
+// RecordPreState stores the pre-execution encoding of an account/storage
+// slot that the block only ever writes to, never reads - a stateless
+// verifier still needs it to compute the correct post-state root.
+func (w *WitnessRecorder) RecordPreState(key common.Hash, encoded []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.preStates[key]; !ok {
+		w.preStates[key] = common.CopyBytes(encoded)
+		w.nodes[key] = common.CopyBytes(encoded)
+	}
+}
+
+// Finalize assembles the recorded data into a Witness, with Codes and
+// State deduplicated and sorted by hash for a deterministic encoding.
+func (w *WitnessRecorder) Finalize() *Witness {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	witness := &Witness{
+		Headers: make([]*types.Header, 0, len(w.headers)),
+		Codes:   make([][]byte, 0, len(w.codes)),
+		State:   make([][]byte, 0, len(w.nodes)),
+	}
+	for _, h := range w.headers {
+		witness.Headers = append(witness.Headers, h)
+	}
+	sort.Slice(witness.Headers, func(i, j int) bool {
+		return witness.Headers[i].Number.Cmp(witness.Headers[j].Number) < 0
+	})
+
+	codeHashes := make([]common.Hash, 0, len(w.codes))
+	for h := range w.codes {
+		codeHashes = append(codeHashes, h)
+	}
+	sort.Slice(codeHashes, func(i, j int) bool { return bytes.Compare(codeHashes[i][:], codeHashes[j][:]) < 0 })
+	for _, h := range codeHashes {
+		witness.Codes = append(witness.Codes, w.codes[h])
+	}
+
+	nodeHashes := make([]common.Hash, 0, len(w.nodes))
+	for h := range w.nodes {
+		nodeHashes = append(nodeHashes, h)
+	}
+	sort.Slice(nodeHashes, func(i, j int) bool { return bytes.Compare(nodeHashes[i][:], nodeHashes[j][:]) < 0 })
+	for _, h := range nodeHashes {
+		witness.State = append(witness.State, w.nodes[h])
+	}
+
+	return witness
+}
+
+// EngineGetPayloadV2 is EngineGetPayloadV1 plus an opt-in witness: when
+// req.WithWitness is set, the exact block already cached for payloadId is
+// re-executed once more under a WitnessRecorder and the resulting blob is
+// attached, so non-stateless clients that never set the flag pay no extra
+// cost. The witness is built from the cached block itself, not a fresh call
+// into the live txpool, so it always matches the ExecutionPayload returned
+// alongside it even if pending transactions have changed since the last
+// buildPayloadLoop tick.
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
+func (s *EthBackendServer) EngineGetPayloadV2(_ context.Context, req *remote.EngineGetPayloadRequest) (*remote.EngineGetPayloadReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
+	if s.config.TerminalTotalDifficulty == nil {
+		return nil, fmt.Errorf("not a proof-of-stake chain")
+	}
+
+	// payload and block must come from a single locked read: reading them
+	// via two separate payloadCache calls (get, then a second lookup for the
+	// block) would let buildPayloadLoop's ticker land a newer
+	// put in between, handing back a payload and a block from different
+	// builds - the exact mismatch this function exists to avoid.
+	payload, block, _, ok := s.payloads.getEntry(req.PayloadId)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload")
+	}
+	// The CL retrieved the payload, so there is no point building on it any further.
+	s.builders.stop(req.PayloadId)
+
+	reply := &remote.EngineGetPayloadReply{ExecutionPayload: &payload}
+
+	if !req.WithWitness {
+		return reply, nil
+	}
+	if block == nil {
+		return nil, fmt.Errorf("no witness available for payload %d", req.PayloadId)
+	}
+
+	witness, err := s.eth.BuildPayloadWitness(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build witness: %w", err)
+	}
+	witnessBytes, err := rlp.EncodeToBytes(witness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode witness: %w", err)
+	}
+	reply.Witness = witnessBytes
+	return reply, nil
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ledgerwatch/erigon-lib/gointerfaces"
 	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
@@ -26,41 +29,82 @@ const (
 	Syncing = "SYNCING"
 	Valid   = "VALID"
 	Invalid = "INVALID"
+
+	Healthy   = "HEALTHY"
+	Unhealthy = "UNHEALTHY"
 )
 
+// beaconCallTimeout is how long EngineHealth will tolerate not hearing an
+// ExchangeTransitionConfiguration call from the CL before reporting the
+// node as unhealthy - a missing beacon connection otherwise fails silently.
+const beaconCallTimeout = 120 * time.Second
+
 // EthBackendAPIVersion
 // 2.0.0 - move all mining-related methods to 'txpool/mining' server
 // 2.1.0 - add NetPeerCount function
-var EthBackendAPIVersion = &types2.VersionReply{Major: 2, Minor: 1, Patch: 0}
+// 2.2.0 - restrict Engine* methods to the JWT-authenticated listener
+// 2.3.0 - add opt-in stateless witness to EngineGetPayloadV2
+var EthBackendAPIVersion = &types2.VersionReply{Major: 2, Minor: 3, Patch: 0}
 
 type EthBackendServer struct {
 	remote.UnimplementedETHBACKENDServer // must be embedded to have forward compatible implementations.
 
-	ctx             context.Context
-	eth             EthBackend
-	events          *Events
-	db              kv.RoDB
-	blockReader     interfaces.BlockReader
-	config          *params.ChainConfig
-	pendingPayloads map[uint64]types2.ExecutionPayload
+	ctx         context.Context
+	eth         EthBackend
+	events      *Events
+	db          kv.RwDB
+	blockReader interfaces.BlockReader
+	config      *params.ChainConfig
+	// payloads is a concurrency-safe LRU of assembled/in-progress
+	// ExecutionPayloads keyed by payloadId, replacing the plain map that
+	// used to back this (gRPC handlers run concurrently).
+	payloads *payloadCache
+	builders *payloadBuilderSet
 	// Send reverse sync starting point to staged sync
 	reverseDownloadCh chan<- types.Block
 	// Notify whether the current block being processed is Valid or not
 	statusCh <-chan core.ExecutionStatus
 	// Last block number sent over via reverseDownloadCh
 	numberSent uint64
+	// lastBeaconCall is the unix nano timestamp of the last
+	// ExchangeTransitionConfiguration call received from the CL, used by
+	// EngineHealth to detect a dropped beacon connection.
+	lastBeaconCall int64
+
+	// wg tracks in-flight RPC handlers so Close can wait for them to drain
+	// instead of tearing the server down from underneath them.
+	wg sync.WaitGroup
+	// shuttingDown is flipped by Close; once set, handlers refuse new work.
+	shuttingDown atomic.Bool
+	// closeCh is closed by Close to unblock handlers parked on
+	// reverseDownloadCh/statusCh, which would otherwise send/receive on a
+	// channel nobody is servicing anymore once shutdown starts.
+	closeCh chan struct{}
 }
 
 type EthBackend interface {
 	Etherbase() (common.Address, error)
 	NetVersion() (uint64, error)
 	NetPeerCount() (uint64, error)
+	// BuildPayload assembles a block on top of parentHash using the pending
+	// transactions known to the txpool, honouring the payload attributes
+	// supplied by the CL in EngineForkchoiceUpdatedV1. The returned receipts
+	// let EIP-6110 deposit requests be derived for the assembled block.
+	BuildPayload(parentHash common.Hash, timestamp uint64, prevRandao common.Hash, suggestedFeeRecipient common.Address) (*types.Block, types.Receipts, error)
+	// BuildPayloadWitness re-executes block - the exact block a prior
+	// BuildPayload call produced - with a WitnessRecorder attached to the
+	// state reader, for EngineGetPayloadV2's opt-in witness mode. It takes
+	// the already-built block rather than build parameters so the witness
+	// it returns always matches the payload already handed to the CL,
+	// regardless of how the live txpool has since changed.
+	BuildPayloadWitness(block *types.Block) (*Witness, error)
 }
 
 func NewEthBackendServer(ctx context.Context, eth EthBackend, db kv.RwDB, events *Events, blockReader interfaces.BlockReader,
 	config *params.ChainConfig, reverseDownloadCh chan<- types.Block, statusCh <-chan core.ExecutionStatus,
 ) *EthBackendServer {
 	return &EthBackendServer{ctx: ctx, eth: eth, events: events, db: db, blockReader: blockReader, config: config,
+		payloads: newPayloadCache(), builders: newPayloadBuilderSet(), closeCh: make(chan struct{}),
 		reverseDownloadCh: reverseDownloadCh, statusCh: statusCh}
 }
 
@@ -69,6 +113,11 @@ func (s *EthBackendServer) Version(context.Context, *emptypb.Empty) (*types2.Ver
 }
 
 func (s *EthBackendServer) Etherbase(_ context.Context, _ *remote.EtherbaseRequest) (*remote.EtherbaseReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	out := &remote.EtherbaseReply{Address: gointerfaces.ConvertAddressToH160(common.Address{})}
 
 	base, err := s.eth.Etherbase()
@@ -81,6 +130,11 @@ func (s *EthBackendServer) Etherbase(_ context.Context, _ *remote.EtherbaseReque
 }
 
 func (s *EthBackendServer) NetVersion(_ context.Context, _ *remote.NetVersionRequest) (*remote.NetVersionReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	id, err := s.eth.NetVersion()
 	if err != nil {
 		return &remote.NetVersionReply{}, err
@@ -89,6 +143,11 @@ func (s *EthBackendServer) NetVersion(_ context.Context, _ *remote.NetVersionReq
 }
 
 func (s *EthBackendServer) NetPeerCount(_ context.Context, _ *remote.NetPeerCountRequest) (*remote.NetPeerCountReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	id, err := s.eth.NetPeerCount()
 	if err != nil {
 		return &remote.NetPeerCountReply{}, err
@@ -97,11 +156,18 @@ func (s *EthBackendServer) NetPeerCount(_ context.Context, _ *remote.NetPeerCoun
 }
 
 func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer remote.ETHBACKEND_SubscribeServer) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+
 	log.Trace("Establishing event subscription channel with the RPC daemon ...")
 	s.events.AddHeaderSubscription(func(h *types.Header) error {
 		select {
 		case <-s.ctx.Done():
 			return nil
+		case <-s.closeCh:
+			return nil
 		case <-subscribeServer.Context().Done():
 			return nil
 		default:
@@ -134,21 +200,37 @@ func (s *EthBackendServer) Subscribe(r *remote.SubscribeRequest, subscribeServer
 	select {
 	case <-subscribeServer.Context().Done():
 	case <-s.ctx.Done():
+	case <-s.closeCh:
 	}
 	log.Info("event subscription channel closed with the RPC daemon")
 	return nil
 }
 
 func (s *EthBackendServer) ProtocolVersion(_ context.Context, _ *remote.ProtocolVersionRequest) (*remote.ProtocolVersionReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	// Hardcoding to avoid import cycle
 	return &remote.ProtocolVersionReply{Id: 66}, nil
 }
 
 func (s *EthBackendServer) ClientVersion(_ context.Context, _ *remote.ClientVersionRequest) (*remote.ClientVersionReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	return &remote.ClientVersionReply{NodeName: common.MakeName("erigon", params.Version)}, nil
 }
 
 func (s *EthBackendServer) Block(ctx context.Context, req *remote.BlockRequest) (*remote.BlockReply, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	tx, err := s.db.BeginRo(ctx)
 	if err != nil {
 		return nil, err
@@ -171,29 +253,45 @@ func (s *EthBackendServer) Block(ctx context.Context, req *remote.BlockRequest)
 }
 
 // EngineExecutePayloadV1, executes payload
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
 func (s *EthBackendServer) EngineExecutePayloadV1(ctx context.Context, req *types2.ExecutionPayload) (*remote.EngineExecutePayloadReply, error) {
+	reply, _, err := s.executePayload(ctx, req, nil)
+	return reply, err
+}
+
+// executePayload backs every EngineExecutePayloadVn method. depositRequests
+// is nil on pre-Prague forks (V1/V2); when non-nil it is folded into the
+// header via RequestsRoot and re-validated against the executed block's own
+// receipts once execution completes - see EngineExecutePayloadV3.
+func (s *EthBackendServer) executePayload(ctx context.Context, req *types2.ExecutionPayload, depositRequests []*DepositRequest) (*remote.EngineExecutePayloadReply, *core.ExecutionStatus, error) {
+	if err := s.enter(); err != nil {
+		return nil, nil, err
+	}
+	defer s.leave()
 
 	if s.config.TerminalTotalDifficulty == nil {
-		return nil, fmt.Errorf("not a proof-of-stake chain")
+		return nil, nil, fmt.Errorf("not a proof-of-stake chain")
 	}
 
 	tx, err := s.db.BeginRo(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer tx.Rollback()
+
 	currentHead := rawdb.ReadHeadBlockHash(tx)
 	// Check mandatory fields
 	if req == nil || req.ParentHash == nil || req.BlockHash == nil || req.Coinbase == nil || req.ExtraData == nil ||
 		req.LogsBloom == nil || req.ReceiptRoot == nil || req.StateRoot == nil || req.Random == nil ||
 		req.Transactions == nil {
 
-		return nil, fmt.Errorf("invalid execution payload")
+		return nil, nil, fmt.Errorf("invalid execution payload")
 	}
 
 	// If another payload is already commissioned then we just reply with syncing
 	headNumber := rawdb.ReadHeaderNumber(tx, currentHead)
 	if headNumber == nil {
-		return nil, fmt.Errorf("cannot find latest block number")
+		return nil, nil, fmt.Errorf("cannot find latest block number")
 	}
 
 	blockHash := gointerfaces.ConvertH256ToHash(req.BlockHash)
@@ -203,7 +301,7 @@ func (s *EthBackendServer) EngineExecutePayloadV1(ctx context.Context, req *type
 		return &remote.EngineExecutePayloadReply{
 			Status:          Syncing,
 			LatestValidHash: gointerfaces.ConvertHashToH256(currentHead),
-		}, nil
+		}, nil, nil
 	}
 	// Let's check if we have parent hash, if we have it we can process the payload right now.
 	// If not, we need to commission it and reverse-download the chain.
@@ -224,7 +322,7 @@ func (s *EthBackendServer) EngineExecutePayloadV1(ctx context.Context, req *type
 		reader.Reset(encodedTransaction)
 		stream.Reset(reader, 0)
 		if transactions[i], err = types.DecodeTransaction(stream); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -251,46 +349,85 @@ func (s *EthBackendServer) EngineExecutePayloadV1(ctx context.Context, req *type
 		ReceiptHash: gointerfaces.ConvertH256ToHash(req.ReceiptRoot),
 		TxHash:      types.DeriveSha(types.Transactions(transactions)),
 	}
+	// deposit_requests (EIP-6110) are only present from Prague onward; pre-Prague
+	// callers (V1/V2) pass depositRequests == nil and RequestsRoot stays unset.
+	if depositRequests != nil {
+		header.RequestsRoot = DeriveDepositRequestsRoot(depositRequests)
+	}
 	// Our execution layer has some problems so we return invalid
 	if header.Hash() != blockHash {
-		return nil, fmt.Errorf("invalid hash for payload. got: %s, wanted: %s", common.Bytes2Hex(blockHash[:]), common.Bytes2Hex(header.Hash().Bytes()))
+		return nil, nil, fmt.Errorf("invalid hash for payload. got: %s, wanted: %s", common.Bytes2Hex(blockHash[:]), common.Bytes2Hex(header.Hash().Bytes()))
 	}
 	log.Info("Received Payload from beacon-chain", "hash", blockHash)
 	// Send the block over
 	s.numberSent = req.BlockNumber
-	s.reverseDownloadCh <- *types.NewBlock(&header, transactions, nil, nil)
+	select {
+	case s.reverseDownloadCh <- *types.NewBlock(&header, transactions, nil, nil):
+	case <-s.closeCh:
+		return nil, nil, fmt.Errorf("EthBackendServer is shutting down")
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
 	// Check if current block is next for execution, if not, commission it and start
 	// Reverse-download the chain from its block number and hash.
 	if header.ParentHash != currentHead {
 		return &remote.EngineExecutePayloadReply{
 			Status:          Syncing,
 			LatestValidHash: gointerfaces.ConvertHashToH256(currentHead),
-		}, nil
+		}, nil, nil
 	}
-	executedStatus := <-s.statusCh
-	if executedStatus.Valid {
+	var executedStatus core.ExecutionStatus
+	select {
+	case executedStatus = <-s.statusCh:
+	case <-s.closeCh:
+		return nil, nil, fmt.Errorf("EthBackendServer is shutting down")
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	if !executedStatus.Valid {
 		return &remote.EngineExecutePayloadReply{
-			Status:          Valid,
-			LatestValidHash: gointerfaces.ConvertHashToH256(blockHash),
-		}, nil
+			Status:          Invalid,
+			LatestValidHash: gointerfaces.ConvertHashToH256(currentHead),
+		}, &executedStatus, nil
 	}
 
-	return &remote.EngineExecutePayloadReply{
-		Status:          Invalid,
-		LatestValidHash: gointerfaces.ConvertHashToH256(currentHead),
-	}, nil
+	if depositRequests != nil {
+		executedRoot, err := DeriveDepositRequestsRootFromReceipts(executedStatus.Receipts, s.config.DepositContractAddress)
+		if err != nil {
+			return nil, &executedStatus, err
+		}
+		if executedRoot != header.RequestsRoot {
+			log.Warn("Deposit requests root mismatch", "payload", header.RequestsRoot, "derived", executedRoot)
+			return &remote.EngineExecutePayloadReply{
+				Status:          Invalid,
+				LatestValidHash: gointerfaces.ConvertHashToH256(currentHead),
+			}, &executedStatus, nil
+		}
+	}
 
+	return &remote.EngineExecutePayloadReply{
+		Status:          Valid,
+		LatestValidHash: gointerfaces.ConvertHashToH256(blockHash),
+	}, &executedStatus, nil
 }
 
 // EngineGetPayloadV1, retrieves previously assembled payload (Validators only)
+// Only served on the JWT-authenticated Engine API listener - see AuthRPCConfig and JWTUnaryInterceptor.
 func (s *EthBackendServer) EngineGetPayloadV1(ctx context.Context, req *remote.EngineGetPayloadRequest) (*types2.ExecutionPayload, error) {
+	if err := s.enter(); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+
 	if s.config.TerminalTotalDifficulty == nil {
 		return nil, fmt.Errorf("not a proof-of-stake chain")
 	}
 
-	payload, ok := s.pendingPayloads[req.PayloadId]
-	if ok {
-		return &payload, nil
+	payload, ok := s.payloads.get(req.PayloadId)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload")
 	}
-	return nil, fmt.Errorf("unknown payload")
+	// The CL retrieved the payload, so there is no point building on it any further.
+	s.builders.stop(req.PayloadId)
+	return &payload, nil
 }
@@ -0,0 +1,64 @@
+package privateapi
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/ledgerwatch/erigon-lib/gointerfaces/remote"
+	"github.com/ledgerwatch/log/v3"
+	"google.golang.org/grpc"
+)
+
+// StartServers stands up the two ETHBACKEND gRPC listeners this package
+// requires: a public listener at publicAddr carrying the regular
+// RPC-daemon-facing methods (Etherbase, NetVersion, Subscribe, ...), and a
+// JWT-authenticated listener, reachable only with a bearer token signed by
+// the secret at auth.JWTSecretPath, carrying the allow-listed Engine API
+// methods the consensus client calls. It must be invoked once from the
+// node's startup sequence, right after NewEthBackendServer. The returned
+// stop func is the node's shutdown hook for this server: it first calls
+// server.Close() to refuse new RPCs and drain any handlers already in
+// flight, then gracefully stops both listeners.
+func StartServers(server *EthBackendServer, publicAddr string, auth AuthRPCConfig) (stop func() error, err error) {
+	secret, err := ObtainJWTSecret(auth.JWTSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading Engine API JWT secret: %w", err)
+	}
+
+	publicServer := grpc.NewServer(grpc.UnaryInterceptor(PublicAPIInterceptor))
+	remote.RegisterETHBACKENDServer(publicServer, server)
+
+	authServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(VhostInterceptor(auth.Vhosts), JWTUnaryInterceptor(secret)),
+		grpc.StreamInterceptor(JWTStreamInterceptor(secret)),
+	)
+	remote.RegisterETHBACKENDServer(authServer, server)
+
+	publicLis, err := net.Listen("tcp", publicAddr)
+	if err != nil {
+		return nil, fmt.Errorf("binding public ETHBACKEND listener: %w", err)
+	}
+	authLis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", auth.Addr, auth.Port))
+	if err != nil {
+		publicLis.Close()
+		return nil, fmt.Errorf("binding authenticated Engine API listener: %w", err)
+	}
+
+	go func() {
+		if err := publicServer.Serve(publicLis); err != nil {
+			log.Warn("public ETHBACKEND listener stopped", "err", err)
+		}
+	}()
+	go func() {
+		if err := authServer.Serve(authLis); err != nil {
+			log.Warn("authenticated Engine API listener stopped", "err", err)
+		}
+	}()
+
+	return func() error {
+		closeErr := server.Close()
+		publicServer.GracefulStop()
+		authServer.GracefulStop()
+		return closeErr
+	}, nil
+}
@@ -0,0 +1,137 @@
+package privateapi
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/ledgerwatch/erigon/common"
+	"github.com/ledgerwatch/erigon/common/hexutil"
+	"github.com/ledgerwatch/log/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Default values for the authenticated Engine API endpoint, analogous to
+// geth's authrpc.addr / authrpc.port / authrpc.vhosts / --jwt-secret flags.
+const (
+	DefaultEngineAPIHost = "127.0.0.1"
+	DefaultEngineAPIPort = 8551
+)
+
+// jwtIssuedAtSkew is the maximum allowed distance between the token's "iat"
+// claim and the current time, in either direction, as required by the
+// Engine API authentication spec.
+const jwtIssuedAtSkew = 60 * time.Second
+
+// ObtainJWTSecret loads the HS256 secret used to authenticate Engine API
+// requests from secretPath. If the file does not exist, a fresh 32-byte
+// secret is generated and written there (hex-encoded, 0x-prefixed) so that
+// subsequent restarts of erigon and the consensus client can agree on it.
+func ObtainJWTSecret(secretPath string) ([]byte, error) {
+	data, err := os.ReadFile(secretPath)
+	if err == nil {
+		jwtSecret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(jwtSecret) == 32 {
+			return jwtSecret, nil
+		}
+		return nil, fmt.Errorf("invalid JWT secret at %s: expected 32 bytes, got %d", secretPath, len(jwtSecret))
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read JWT secret file %s: %w", secretPath, err)
+	}
+
+	jwtSecret := make([]byte, 32)
+	if _, err := rand.Read(jwtSecret); err != nil {
+		return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	if err := os.WriteFile(secretPath, []byte(hexutil.Encode(jwtSecret)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write JWT secret to %s: %w", secretPath, err)
+	}
+	log.Info("Generated Engine API JWT secret", "path", secretPath)
+	return jwtSecret, nil
+}
+
+// validateJWT checks that tokenString is a well-formed, HS256-signed JWT
+// produced with secret, and that its "iat" claim falls within jwtIssuedAtSkew
+// of now - this is what prevents a captured token from being replayed
+// indefinitely.
+func validateJWT(secret []byte, tokenString string) error {
+	claims := jwt.RegisteredClaims{}
+	// The library's own claims validation rejects any iat in the future, so
+	// it is skipped here and iat is checked against jwtIssuedAtSkew below.
+	parser := jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}, SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return fmt.Errorf("invalid token")
+	}
+	if claims.IssuedAt == nil {
+		return fmt.Errorf("missing iat claim")
+	}
+	if skew := time.Since(claims.IssuedAt.Time); skew > jwtIssuedAtSkew || skew < -jwtIssuedAtSkew {
+		return fmt.Errorf("iat claim out of range: %s", skew)
+	}
+	return nil
+}
+
+func bearerTokenFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("no metadata in context")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", fmt.Errorf("missing authorization header")
+	}
+	const prefix = "Bearer "
+	if !strings.HasPrefix(values[0], prefix) {
+		return "", fmt.Errorf("authorization header must use Bearer scheme")
+	}
+	return strings.TrimPrefix(values[0], prefix), nil
+}
+
+// JWTUnaryInterceptor returns a gRPC unary interceptor that should be
+// installed on the authenticated Engine API listener. It rejects any call
+// that does not carry a valid "Authorization: Bearer <jwt>" header signed
+// with secret.
+func JWTUnaryInterceptor(secret []byte) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		token, err := bearerTokenFromContext(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+		if err := validateJWT(secret, token); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid JWT: %v", err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// JWTStreamInterceptor is the streaming counterpart of JWTUnaryInterceptor,
+// needed because Subscribe and similar calls are server-streaming RPCs.
+func JWTStreamInterceptor(secret []byte) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		token, err := bearerTokenFromContext(ss.Context())
+		if err != nil {
+			return status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+		if err := validateJWT(secret, token); err != nil {
+			return status.Errorf(codes.Unauthenticated, "invalid JWT: %v", err)
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,63 @@
+package privateapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func signToken(t *testing.T, secret []byte, issuedAt time.Time) string {
+	t.Helper()
+	claims := jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issuedAt)}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("signing test token: %v", err)
+	}
+	return token
+}
+
+func TestValidateJWT_AcceptsFreshToken(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	token := signToken(t, secret, time.Now())
+
+	if err := validateJWT(secret, token); err != nil {
+		t.Fatalf("expected a fresh, correctly-signed token to validate, got: %v", err)
+	}
+}
+
+func TestValidateJWT_RejectsWrongSecret(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	wrongSecret := []byte("fedcba9876543210fedcba9876543210")
+	token := signToken(t, wrongSecret, time.Now())
+
+	if err := validateJWT(secret, token); err == nil {
+		t.Fatal("expected a token signed with a different secret to be rejected")
+	}
+}
+
+func TestValidateJWT_RejectsStaleIssuedAt(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	token := signToken(t, secret, time.Now().Add(-2*jwtIssuedAtSkew))
+
+	if err := validateJWT(secret, token); err == nil {
+		t.Fatal("expected a token with an iat far outside jwtIssuedAtSkew to be rejected")
+	}
+}
+
+func TestValidateJWT_RejectsFutureIssuedAt(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	token := signToken(t, secret, time.Now().Add(2*jwtIssuedAtSkew))
+
+	if err := validateJWT(secret, token); err == nil {
+		t.Fatal("expected a token with an iat far in the future to be rejected")
+	}
+}
+
+func TestValidateJWT_RejectsMalformedToken(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	if err := validateJWT(secret, "not.a.jwt"); err == nil {
+		t.Fatal("expected a malformed token string to be rejected")
+	}
+}
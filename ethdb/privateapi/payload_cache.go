@@ -0,0 +1,116 @@
+package privateapi
+
+import (
+	"container/list"
+	"sync"
+
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+	"github.com/ledgerwatch/erigon/core/types"
+)
+
+// maxPendingPayloads bounds how many in-progress/assembled payloads we keep
+// around at once. Payload building is driven entirely by the CL polling
+// EngineGetPayloadV1, so a handful of slots is enough to cover a CL that is
+// juggling a few forkchoice updates in flight.
+const maxPendingPayloads = 32
+
+// payloadCache is a small mutex-protected LRU keyed by payloadId, replacing
+// the plain map that used to back pendingPayloads. It exists because gRPC
+// handlers run concurrently, so ForkchoiceUpdatedV1 (writer) and
+// EngineGetPayloadV1 (reader) can race on the same payloadId.
+type payloadCache struct {
+	mu       sync.Mutex
+	entries  map[uint64]*list.Element
+	order    *list.List // front = most recently used
+	capacity int
+}
+
+type payloadCacheEntry struct {
+	id      uint64
+	payload types2.ExecutionPayload
+	// depositRequests are the EIP-6110 requests derived from the receipts
+	// produced while building this payload locally; nil pre-Prague or when
+	// the payload came from the CL rather than our own builder.
+	depositRequests []*DepositRequest
+	// block is the exact block buildPayloadLoop assembled payload from.
+	// EngineGetPayloadV2 needs the actual block, not just its build
+	// parameters, to regenerate a witness for the exact transactions
+	// already committed to in payload - the live txpool can select a
+	// different set by the time GetPayload is called.
+	block *types.Block
+}
+
+func newPayloadCache() *payloadCache {
+	return &payloadCache{
+		entries:  make(map[uint64]*list.Element),
+		order:    list.New(),
+		capacity: maxPendingPayloads,
+	}
+}
+
+func (c *payloadCache) put(id uint64, payload types2.ExecutionPayload, depositRequests []*DepositRequest, block *types.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		entry := el.Value.(*payloadCacheEntry)
+		entry.payload, entry.depositRequests, entry.block = payload, depositRequests, block
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&payloadCacheEntry{id: id, payload: payload, depositRequests: depositRequests, block: block})
+	c.entries[id] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*payloadCacheEntry).id)
+		}
+	}
+}
+
+func (c *payloadCache) get(id uint64) (types2.ExecutionPayload, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return types2.ExecutionPayload{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*payloadCacheEntry).payload, true
+}
+
+// getDepositRequests returns the deposit requests recorded alongside a
+// locally-built payload, if any.
+func (c *payloadCache) getDepositRequests(id uint64) []*DepositRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return nil
+	}
+	return el.Value.(*payloadCacheEntry).depositRequests
+}
+
+// getEntry returns the payload, its block, and its deposit requests as they
+// stood at a single point in time. Callers that need more than one of these
+// together (EngineGetPayloadV2 needs payload+block) must use this instead of
+// two separately-locked lookups: buildPayloadLoop's periodic put can land
+// between two separately-locked reads and hand back a payload and block from
+// different builds.
+func (c *payloadCache) getEntry(id uint64) (types2.ExecutionPayload, *types.Block, []*DepositRequest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return types2.ExecutionPayload{}, nil, nil, false
+	}
+	c.order.MoveToFront(el)
+	entry := el.Value.(*payloadCacheEntry)
+	return entry.payload, entry.block, entry.depositRequests, true
+}
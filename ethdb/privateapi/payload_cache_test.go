@@ -0,0 +1,67 @@
+package privateapi
+
+import (
+	"testing"
+
+	types2 "github.com/ledgerwatch/erigon-lib/gointerfaces/types"
+)
+
+func TestPayloadCache_GetAfterPut(t *testing.T) {
+	c := newPayloadCache()
+	c.put(1, types2.ExecutionPayload{BlockNumber: 1}, nil, nil)
+
+	payload, ok := c.get(1)
+	if !ok {
+		t.Fatal("expected payload 1 to be present after put")
+	}
+	if payload.BlockNumber != 1 {
+		t.Fatalf("got BlockNumber %d, want 1", payload.BlockNumber)
+	}
+}
+
+func TestPayloadCache_EvictsOldestBeyondCapacity(t *testing.T) {
+	c := newPayloadCache()
+	c.capacity = 2
+
+	c.put(1, types2.ExecutionPayload{BlockNumber: 1}, nil, nil)
+	c.put(2, types2.ExecutionPayload{BlockNumber: 2}, nil, nil)
+	c.put(3, types2.ExecutionPayload{BlockNumber: 3}, nil, nil)
+
+	if _, ok := c.get(1); ok {
+		t.Fatal("expected the oldest entry to be evicted once capacity was exceeded")
+	}
+	if _, ok := c.get(2); !ok {
+		t.Fatal("expected entry 2 to still be cached")
+	}
+	if _, ok := c.get(3); !ok {
+		t.Fatal("expected entry 3 to still be cached")
+	}
+}
+
+func TestPayloadCache_GetRefreshesRecency(t *testing.T) {
+	c := newPayloadCache()
+	c.capacity = 2
+
+	c.put(1, types2.ExecutionPayload{BlockNumber: 1}, nil, nil)
+	c.put(2, types2.ExecutionPayload{BlockNumber: 2}, nil, nil)
+
+	// Touch 1 so it becomes most-recently-used instead of 2.
+	if _, ok := c.get(1); !ok {
+		t.Fatal("expected entry 1 to be cached")
+	}
+	c.put(3, types2.ExecutionPayload{BlockNumber: 3}, nil, nil)
+
+	if _, ok := c.get(2); ok {
+		t.Fatal("expected entry 2 to be evicted since it was least recently used")
+	}
+	if _, ok := c.get(1); !ok {
+		t.Fatal("expected entry 1 to survive since get() refreshed its recency")
+	}
+}
+
+func TestPayloadCache_GetUnknownID(t *testing.T) {
+	c := newPayloadCache()
+	if _, ok := c.get(42); ok {
+		t.Fatal("expected get on an unknown payloadId to report not found")
+	}
+}
@@ -0,0 +1,66 @@
+package privateapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/log/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// closeTimeout bounds how long Close waits for in-flight RPC handlers to
+// drain before giving up and returning anyway, so a wedged CL request can't
+// hang node shutdown forever.
+const closeTimeout = 30 * time.Second
+
+// enter must be called at the top of every exported RPC handler, paired
+// with a deferred leave. It refuses new work once Close has started,
+// instead of letting it race a teardown in progress and panic on a
+// send/receive over an already-closed channel.
+//
+// wg.Add must happen before the shuttingDown check, not after: checking
+// first and only then adding would let a handler observe shuttingDown ==
+// false, then have Close flip the flag and return from a wg.Wait() that
+// never saw this handler's Add, and only then have the handler call
+// wg.Add and proceed - i.e. Close could report "drained" while an RPC is
+// still mid-flight. Adding first means Close's wg.Wait() is guaranteed to
+// block on any handler that got past the check before Close ran.
+func (s *EthBackendServer) enter() error {
+	s.wg.Add(1)
+	if s.shuttingDown.Load() {
+		s.wg.Done()
+		return status.Error(codes.Unavailable, "EthBackendServer is shutting down")
+	}
+	return nil
+}
+
+func (s *EthBackendServer) leave() {
+	s.wg.Done()
+}
+
+// Close flips shuttingDown so every handler's enter() starts refusing new
+// work, unblocks any handler parked on reverseDownloadCh/statusCh via
+// closeCh, and waits for in-flight handlers to finish (up to closeTimeout)
+// before returning. It must be called exactly once, as part of the erigon
+// shutdown sequence, before the gRPC server and its channels are torn down.
+func (s *EthBackendServer) Close() error {
+	if !s.shuttingDown.CompareAndSwap(false, true) {
+		return fmt.Errorf("EthBackendServer.Close called twice")
+	}
+	close(s.closeCh)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(closeTimeout):
+		log.Warn("EthBackendServer.Close timed out waiting for in-flight RPCs", "timeout", closeTimeout)
+		return fmt.Errorf("timed out after %s waiting for in-flight RPCs to drain", closeTimeout)
+	}
+}
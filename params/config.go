@@ -0,0 +1,26 @@
+package params
+
+import (
+	"math/big"
+
+	"github.com/ledgerwatch/erigon/common"
+)
+
+// ChainConfig is a subset of the real erigon chain config: only the fields
+// ethdb/privateapi's Engine API implementation reads.
+type ChainConfig struct {
+	// TerminalTotalDifficulty is the network's TTD, the point at which PoW
+	// block production stops and the merge transition begins. nil on
+	// chains that have not scheduled the merge.
+	TerminalTotalDifficulty *big.Int
+	// TerminalBlockHash/TerminalBlockNumber optionally pin the exact
+	// terminal PoW block, for ExchangeTransitionConfigurationV1 to
+	// cross-check against the CL without having to find it by TTD.
+	TerminalBlockHash   common.Hash
+	TerminalBlockNumber *big.Int
+
+	// DepositContractAddress is the beacon deposit contract erigon watches
+	// for DepositEvent logs to assemble EIP-6110 execution-layer-triggered
+	// deposit requests. The zero address disables EIP-6110 processing.
+	DepositContractAddress common.Address
+}
@@ -0,0 +1,5 @@
+package params
+
+// Version is the node's user-agent version string, as reported by
+// ClientVersion.
+var Version = "2.0.0"